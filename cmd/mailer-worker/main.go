@@ -0,0 +1,130 @@
+// Command mailer-worker consumes the "mail" rmq queue populated by the web
+// app and delivers each message over SMTP. Running it as a separate binary
+// means it can be scaled independently of the web tier, and a crash here
+// never drops a queued email — rmq redelivers unacked jobs on restart.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adjust/rmq/v4"
+
+	"subscription/internal/mailer"
+)
+
+const (
+	numConsumers    = 5
+	prefetchLimit   = 50
+	pollDuration    = time.Second
+	cleanerInterval = 10 * time.Minute
+)
+
+func main() {
+	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
+	errorLog := log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
+
+	redisAddr := os.Getenv("REDIS")
+	if redisAddr == "" {
+		redisAddr = "localhost:6379"
+	}
+
+	connErrChan := make(chan error)
+	go func() {
+		for err := range connErrChan {
+			errorLog.Println("rmq connection error:", err)
+		}
+	}()
+
+	conn, err := rmq.OpenConnection("mailer-worker", "tcp", redisAddr, 1, connErrChan)
+	if err != nil {
+		errorLog.Panicln("opening rmq connection:", err)
+	}
+
+	queue, err := conn.OpenQueue("mail")
+	if err != nil {
+		errorLog.Panicln("opening mail queue:", err)
+	}
+
+	// rmq's Delivery.Push falls back to Reject (a dead end the Cleaner
+	// never recovers) unless a push queue is configured. Pushing back onto
+	// "mail" itself keeps a transient send failure in the retry loop.
+	queue.SetPushQueue(queue)
+
+	if err := queue.StartConsuming(prefetchLimit, pollDuration); err != nil {
+		errorLog.Panicln("starting consumer loop:", err)
+	}
+
+	smtp := mailer.ConfigFromEnv()
+	for i := 0; i < numConsumers; i++ {
+		if _, err := queue.AddConsumer("mail-consumer", &mailConsumer{
+			smtp:     smtp,
+			infoLog:  infoLog,
+			errorLog: errorLog,
+		}); err != nil {
+			errorLog.Panicln("adding consumer:", err)
+		}
+	}
+
+	// returns unacked deliveries (e.g. from a worker that crashed mid-send)
+	// to the ready queue so a dead consumer never loses a job; rejected
+	// deliveries are poison messages and are deliberately left alone
+	go runCleaner(conn, infoLog, errorLog)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	infoLog.Println("shutting down mailer-worker...")
+	<-conn.StopAllConsuming()
+}
+
+type mailConsumer struct {
+	smtp     mailer.Config
+	infoLog  *log.Logger
+	errorLog *log.Logger
+}
+
+func (c *mailConsumer) Consume(delivery rmq.Delivery) {
+	var msg mailer.Message
+	if err := json.Unmarshal([]byte(delivery.Payload()), &msg); err != nil {
+		c.errorLog.Println("discarding unparseable mail job:", err)
+		delivery.Reject()
+		return
+	}
+
+	if err := c.smtp.Send(msg); err != nil {
+		// Push puts the job back on the ready queue for another attempt.
+		// Reject is reserved for poison messages (above) since rmq's
+		// Cleaner never recovers the rejected queue.
+		c.errorLog.Println("sending mail, pushing back for retry:", err)
+		if pushErr := delivery.Push(); pushErr != nil {
+			c.errorLog.Println("pushing mail job back for retry:", pushErr)
+		}
+		return
+	}
+
+	c.infoLog.Printf("sent mail to %s: %s", msg.To, msg.Subject)
+	delivery.Ack()
+}
+
+func runCleaner(conn rmq.Connection, infoLog, errorLog *log.Logger) {
+	cleaner := rmq.NewCleaner(conn)
+	ticker := time.NewTicker(cleanerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		returned, err := cleaner.Clean()
+		if err != nil {
+			errorLog.Println("cleaning stale mail deliveries:", err)
+			continue
+		}
+		if returned > 0 {
+			infoLog.Printf("cleaner returned %d stale mail job(s) to the ready queue", returned)
+		}
+	}
+}
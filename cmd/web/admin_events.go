@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// adminForceLogout publishes a user.logout event so every replica destroys
+// that user's sessions, not just the one that served this request.
+func (app *Config) adminForceLogout(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if err := app.publishEvent("user.logout", LogoutEvent{UserID: userID}); err != nil {
+		app.ErrorLog.Println("publishing user.logout event:", err)
+		http.Error(w, "failed to force logout", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// adminChangePlan publishes a subscription.updated event so every replica
+// can push the new plan to any browser the user has open over SSE.
+func (app *Config) adminChangePlan(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Plan string `json:"plan"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Plan == "" {
+		http.Error(w, "plan is required", http.StatusBadRequest)
+		return
+	}
+
+	evt := SubscriptionUpdatedEvent{UserID: userID, Plan: body.Plan}
+	if err := app.publishEvent("subscription.updated", evt); err != nil {
+		app.ErrorLog.Println("publishing subscription.updated event:", err)
+		http.Error(w, "failed to change plan", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// adminListJobs returns every registered job along with its cron
+// expression and last run time.
+func (app *Config) adminListJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(app.Jobs.list()); err != nil {
+		app.ErrorLog.Println("encoding job list:", err)
+	}
+}
+
+// adminRunJob triggers an immediate, lock-guarded run of the named job. It
+// reports 409 rather than 202 whenever the job did not run (the lock was held
+// by another replica, or could not be taken at all), so a caller can't
+// mistake a skipped run for one that actually happened.
+func (app *Config) adminRunJob(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	ran, err := app.RunJobNow(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !ran {
+		http.Error(w, "job did not run: its lock is held elsewhere or could not be taken", http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
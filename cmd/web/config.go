@@ -0,0 +1,35 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/adjust/rmq/v4"
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-co-op/gocron"
+	"github.com/gomodule/redigo/redis"
+	"subscription/data"
+	"subscription/internal/ratelimit"
+)
+
+// Config holds the application-wide dependencies that are shared across
+// handlers, background goroutines and the HTTP server itself.
+type Config struct {
+	Session       *scs.SessionManager
+	DB            *sql.DB
+	Wait          *sync.WaitGroup
+	InfoLog       *log.Logger
+	ErrorLog      *log.Logger
+	Models        data.Models
+	Mailer        Mail
+	RedisPool     *redis.Pool
+	MailQueueConn rmq.Connection
+	Scheduler     *gocron.Scheduler
+	Jobs          *JobRegistry
+	RateLimiter   *ratelimit.Limiter
+	RateBuckets   map[string]ratelimit.Bucket
+	Broker        *sseBroker
+	Srv           *http.Server
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	eventPingInterval        = 30 * time.Second
+	eventReconnectMinBackoff = time.Second
+	eventReconnectMaxBackoff = 30 * time.Second
+)
+
+// LogoutEvent is published on "user.logout" so every replica destroys that
+// user's sessions, regardless of which one the admin action landed on.
+type LogoutEvent struct {
+	UserID int `json:"user_id"`
+}
+
+// SubscriptionUpdatedEvent is published on "subscription.updated" whenever
+// an admin changes a user's plan, so any connected browser can be pushed a
+// live update over SSE.
+type SubscriptionUpdatedEvent struct {
+	UserID int    `json:"user_id"`
+	Plan   string `json:"plan"`
+}
+
+// listenForEvents subscribes to the admin pub/sub channels for the life of
+// the process, redelivering to dispatchEvent and reconnecting with backoff
+// whenever the subscription connection drops.
+func (app *Config) listenForEvents() {
+	backoff := eventReconnectMinBackoff
+
+	for {
+		connected, err := app.subscribeAndServe()
+		if err != nil {
+			app.ErrorLog.Println("event subscriber disconnected:", err)
+		}
+
+		// A subscription that actually confirmed (as opposed to failing to
+		// dial/subscribe at all) means Redis was healthy for a while; don't
+		// let a handful of lifetime reconnects ratchet backoff up to its
+		// 30s ceiling and stay there.
+		if connected {
+			backoff = eventReconnectMinBackoff
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > eventReconnectMaxBackoff {
+			backoff = eventReconnectMaxBackoff
+		}
+	}
+}
+
+// subscribeAndServe blocks until the subscription connection fails, and
+// reports whether it ever reached a confirmed, live subscription.
+func (app *Config) subscribeAndServe() (connected bool, err error) {
+	conn := app.RedisPool.Get()
+	defer conn.Close()
+
+	psc := redis.PubSubConn{Conn: conn}
+	if err := psc.Subscribe("user.logout", "subscription.updated"); err != nil {
+		return false, err
+	}
+	defer psc.Unsubscribe()
+
+	done := make(chan error, 1)
+	confirmed := make(chan struct{}, 1)
+	go func() {
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				app.dispatchEvent(v.Channel, v.Data)
+			case redis.Subscription:
+				app.InfoLog.Printf("event bus: %s %s (%d channels)", v.Kind, v.Channel, v.Count)
+				select {
+				case confirmed <- struct{}{}:
+				default:
+				}
+			case error:
+				done <- v
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(eventPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-confirmed:
+			connected = true
+		case <-ticker.C:
+			// keeps the connection from being reaped as idle during quiet
+			// periods; a failed ping surfaces a dead connection immediately
+			// instead of waiting for the next real event
+			if err := psc.Ping(""); err != nil {
+				return connected, err
+			}
+		case err := <-done:
+			return connected, err
+		}
+	}
+}
+
+func (app *Config) dispatchEvent(channel string, data []byte) {
+	switch channel {
+	case "user.logout":
+		var evt LogoutEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			app.ErrorLog.Println("decoding user.logout event:", err)
+			return
+		}
+		app.handleUserLogout(evt)
+
+	case "subscription.updated":
+		var evt SubscriptionUpdatedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			app.ErrorLog.Println("decoding subscription.updated event:", err)
+			return
+		}
+		app.handleSubscriptionUpdated(evt)
+	}
+}
+
+// handleUserLogout destroys every session belonging to evt.UserID on this
+// replica. scs has no destroy-by-user-id primitive, so we iterate the
+// store's active sessions and destroy the ones that match.
+func (app *Config) handleUserLogout(evt LogoutEvent) {
+	err := app.Session.Iterate(context.Background(), func(ctx context.Context) error {
+		if app.Session.GetInt(ctx, "userID") != evt.UserID {
+			return nil
+		}
+		return app.Session.Destroy(ctx)
+	})
+	if err != nil {
+		app.ErrorLog.Println("destroying sessions for user", evt.UserID, ":", err)
+	}
+}
+
+func (app *Config) handleSubscriptionUpdated(evt SubscriptionUpdatedEvent) {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		app.ErrorLog.Println("marshaling subscription.updated event:", err)
+		return
+	}
+	app.Broker.publish(evt.UserID, string(payload))
+}
+
+// publishEvent is used by the admin handlers (force logout, plan change) to
+// broadcast to every replica over Redis pub/sub.
+func (app *Config) publishEvent(channel string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	conn := app.RedisPool.Get()
+	defer conn.Close()
+
+	_, err = conn.Do("PUBLISH", channel, data)
+	return err
+}
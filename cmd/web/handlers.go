@@ -0,0 +1,24 @@
+package main
+
+import "net/http"
+
+// These handlers are deliberately thin stubs: the rate limiting middleware
+// in routes.go is what this file exists to exercise. Request parsing,
+// validation and the actual login/signup/reset flows belong here as they're
+// built out.
+
+func (app *Config) handleLogin(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+func (app *Config) handleSignup(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+func (app *Config) handlePasswordReset(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+func (app *Config) handleMailSend(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
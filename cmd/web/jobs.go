@@ -0,0 +1,47 @@
+package main
+
+// registerScheduledJobs wires up all of the app's recurring tasks. Called
+// once from main() after the scheduler and Redis pool are ready.
+func (app *Config) registerScheduledJobs() {
+	jobs := []struct {
+		name string
+		cron string
+		fn   func() error
+	}{
+		{"renewal-reminders", "0 9 * * *", app.SendRenewalReminders},
+		{"expiring-plan-notifications", "0 10 * * *", app.SendExpiringPlanNotifications},
+		{"invoice-generation", "0 1 1 * *", app.GenerateInvoices},
+		{"unverified-account-cleanup", "0 3 * * *", app.CleanupUnverifiedAccounts},
+	}
+
+	for _, j := range jobs {
+		if err := app.RegisterJob(j.name, j.cron, j.fn); err != nil {
+			app.ErrorLog.Println("registering job", j.name, err)
+		}
+	}
+}
+
+// SendRenewalReminders runs as the "renewal-reminders" job. Its body is
+// empty: it queries no subscriptions, sends no reminders and returns nil.
+func (app *Config) SendRenewalReminders() error {
+	return nil
+}
+
+// SendExpiringPlanNotifications runs as the "expiring-plan-notifications"
+// job. Its body is empty: it queries no plans, sends no notifications and
+// returns nil.
+func (app *Config) SendExpiringPlanNotifications() error {
+	return nil
+}
+
+// GenerateInvoices runs as the "invoice-generation" job. Its body is empty:
+// it creates no invoices and returns nil.
+func (app *Config) GenerateInvoices() error {
+	return nil
+}
+
+// CleanupUnverifiedAccounts runs as the "unverified-account-cleanup" job.
+// Its body is empty: it deletes no accounts and returns nil.
+func (app *Config) CleanupUnverifiedAccounts() error {
+	return nil
+}
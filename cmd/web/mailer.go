@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"subscription/internal/mailer"
+)
+
+// Mail wraps the "mail" rmq queue that outbound messages are published to.
+// The actual SMTP delivery happens out of process, in cmd/mailer-worker, so
+// a web app restart or deploy can never drop an in-flight email.
+type Mail struct {
+	Queue rmqQueue
+}
+
+// rmqQueue is the subset of rmq.Queue that SendMail needs, so mailer.go
+// doesn't have to import the rmq package directly.
+type rmqQueue interface {
+	PublishBytes(b []byte) error
+}
+
+func (app *Config) createMail() Mail {
+	queue, err := app.MailQueueConn.OpenQueue("mail")
+	if err != nil {
+		app.ErrorLog.Panicln("opening mail queue:", err)
+	}
+
+	return Mail{Queue: queue}
+}
+
+// SendMail serializes msg and publishes it onto the "mail" queue for the
+// mailer-worker to pick up and deliver.
+func (app *Config) SendMail(msg mailer.Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling mail message: %w", err)
+	}
+
+	if err := app.Mailer.Queue.PublishBytes(data); err != nil {
+		return fmt.Errorf("publishing mail message: %w", err)
+	}
+
+	return nil
+}
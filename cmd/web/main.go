@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"subscription/data"
+	"subscription/internal/ratelimit"
 	"sync"
 	"syscall"
 	"time"
@@ -23,6 +27,9 @@ import (
 
 const webPort = "80"
 
+// defaultShutdownTimeout is used when SHUTDOWN_TIMEOUT is unset or invalid.
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
 	// connect to DB
 	db := initDB()
@@ -31,7 +38,8 @@ func main() {
 	gob.Register(data.User{})
 
 	// create sessions
-	session := initSession()
+	redisPool := initRedis()
+	session := initSession(redisPool)
 
 	// create loggers
 	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
@@ -40,37 +48,67 @@ func main() {
 	// create wait group
 	wg := sync.WaitGroup{}
 
+	rateBuckets, err := ratelimit.BucketsFromEnv()
+	if err != nil {
+		log.Panicln("loading rate limit buckets:", err)
+	}
+
 	// set up application config
+	mailQueueErrChan := make(chan error)
 	app := Config{
-		Session:  session,
-		DB:       db,
-		Wait:     &wg,
-		InfoLog:  infoLog,
-		ErrorLog: errorLog,
-		Models:   data.New(db),
+		Session:       session,
+		DB:            db,
+		Wait:          &wg,
+		InfoLog:       infoLog,
+		ErrorLog:      errorLog,
+		Models:        data.New(db),
+		RedisPool:     redisPool,
+		MailQueueConn: initMailQueue(mailQueueErrChan),
+		Scheduler:     initScheduler(),
+		Jobs:          newJobRegistry(),
+		RateLimiter:   ratelimit.New(redisPool),
+		RateBuckets:   rateBuckets,
+		Broker:        newSSEBroker(),
 	}
+	go app.logMailQueueErrors(mailQueueErrChan)
 
-	// set up mail
+	// set up mail: SendMail publishes onto the "mail" queue, consumed by the
+	// separately-scaled cmd/mailer-worker binary
 	app.Mailer = app.createMail()
-	go app.listenForMail()
 
-	// shutdown gracefully
-	go app.listenForShutdown()
+	// register recurring subscription tasks (renewal reminders, invoice
+	// generation, etc.), each guarded by a distributed lock so only one
+	// replica runs a given job per tick
+	app.registerScheduledJobs()
+
+	// propagate admin actions (force logout, plan change) to every
+	// replica instantly via Redis pub/sub
+	go app.listenForEvents()
+
+	// shutdown gracefully: listenForShutdown only runs shutdown() to
+	// completion and signals shutdownDone: Srv.Shutdown makes serve() return
+	// well before the rest of the cleanup (waitgroup, queues, DB, Redis) is
+	// done, so main must wait for shutdownDone rather than exiting as soon
+	// as serve() returns.
+	shutdownDone := make(chan struct{})
+	go app.listenForShutdown(shutdownDone)
 
 	// start webserver
 	app.serve()
 
+	<-shutdownDone
+	os.Exit(0)
 }
 
 func (app *Config) serve() {
-	srv := &http.Server{
+	app.Srv = &http.Server{
 		Addr:    fmt.Sprintf(":%s", webPort),
 		Handler: app.routes(),
 	}
 
 	app.InfoLog.Println("Starting web server...")
-	err := srv.ListenAndServe()
-	if err != nil {
+	err := app.Srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Panicln(err)
 	}
 }
@@ -114,9 +152,9 @@ func openDB() (*sql.DB, error) {
 
 }
 
-func initSession() *scs.SessionManager {
+func initSession(redisPool *redis.Pool) *scs.SessionManager {
 	session := scs.New()
-	session.Store = redisstore.New(initRedis())
+	session.Store = redisstore.New(redisPool)
 	session.Lifetime = 24 * time.Hour
 	session.Cookie.Persist = true
 	session.Cookie.SameSite = http.SameSiteLaxMode
@@ -125,56 +163,75 @@ func initSession() *scs.SessionManager {
 	return session
 }
 
-func initRedis() *redis.Pool {
-	redisPool := &redis.Pool{
-		MaxIdle: 10,
-		Dial: func() (redis.Conn, error) {
-			return redis.Dial("tcp", os.Getenv("REDIS"))
-		},
-	}
-	return redisPool
-}
-
-func (app *Config) listenForShutdown() {
+func (app *Config) listenForShutdown(done chan<- struct{}) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	app.shutdown()
-	os.Exit(0)
+	close(done)
 }
 func (app *Config) shutdown() {
 	// perform clean up tasks
 	app.InfoLog.Println("running clean up tasks...")
 
-	// block until waitgroup is empty
+	// stop accepting new connections and let in-flight requests finish,
+	// bounded by SHUTDOWN_TIMEOUT so a stuck handler can't hang the process
+	if app.Srv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+		defer cancel()
+
+		if err := app.Srv.Shutdown(ctx); err != nil {
+			app.ErrorLog.Println("graceful shutdown failed, forcing close:", err)
+			app.Srv.Close()
+		}
+	}
+
+	app.Scheduler.Stop()
+
+	// block until background work drains
 	app.Wait.Wait()
-	app.Mailer.DoneChan <- true
 
-	close(app.Mailer.ErrorChan)
-	close(app.Mailer.DoneChan)
-	close(app.Mailer.MailerChan)
+	if err := app.MailQueueConn.StopAllConsuming(); err != nil {
+		app.ErrorLog.Println("error stopping mail queue consumers:", err)
+	}
+
+	if err := app.DB.Close(); err != nil {
+		app.ErrorLog.Println("error closing DB:", err)
+	}
+
+	if app.RedisPool != nil {
+		if err := app.RedisPool.Close(); err != nil {
+			app.ErrorLog.Println("error closing Redis pool:", err)
+		}
+	}
 
-	app.InfoLog.Println("closing channels and shutting down application...")
+	app.InfoLog.Println("shutting down application...")
 
 }
-func (app *Config) createMail() Mail {
-	// create channels
-	errorChan := make(chan error)
-	mailerChan := make(chan Message, 100)
-	mailerDoneChan := make(chan bool)
-
-	m := Mail{
-		Domain:      "localhost",
-		Host:        "localhost",
-		Port:        1025,
-		Encryption:  "none",
-		FromName:    "Info",
-		FromAddress: "info@mycompany.com",
-		Wait:        app.Wait,
-		ErrorChan:   errorChan,
-		MailerChan:  mailerChan,
-		DoneChan:    mailerDoneChan,
+
+// logMailQueueErrors drains rmq's internal error channel to the app's error
+// log so publish failures on the "mail" queue aren't silently dropped.
+func (app *Config) logMailQueueErrors(errChan <-chan error) {
+	for err := range errChan {
+		app.ErrorLog.Println("mail queue error:", err)
+	}
+}
+
+// shutdownTimeout returns the grace period for in-flight HTTP requests during
+// shutdown, configurable via SHUTDOWN_TIMEOUT (e.g. "15s").
+func shutdownTimeout() time.Duration {
+	raw := os.Getenv("SHUTDOWN_TIMEOUT")
+	if raw == "" {
+		return defaultShutdownTimeout
+	}
+
+	if secs, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+
+	if d, err := time.ParseDuration(raw); err == nil {
+		return d
 	}
 
-	return m
+	return defaultShutdownTimeout
 }
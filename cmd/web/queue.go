@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/adjust/rmq/v4"
+)
+
+// initMailQueue opens the rmq connection used to publish outbound mail
+// jobs. It dials the REDIS value as one plain host:port (rmq manages its own
+// connection pool internally); the address list, Sentinel, password, DB and
+// TLS settings that initRedis applies for sessions are not used here.
+func initMailQueue(errChan chan<- error) rmq.Connection {
+	addr := os.Getenv("REDIS")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	conn, err := rmq.OpenConnection("web", "tcp", addr, 1, errChan)
+	if err != nil {
+		log.Panicln("opening mail queue connection:", err)
+	}
+
+	return conn
+}
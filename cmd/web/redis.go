@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// RedisConfig describes how to reach the Redis deployment backing sessions
+// and (later) the other Redis-backed subsystems. It is populated entirely
+// from the environment so the same binary works against a single instance
+// in dev and a Sentinel-fronted HA cluster in production.
+type RedisConfig struct {
+	Addresses      []string // host:port pairs; for Sentinel these are the sentinel addresses
+	Password       string
+	DB             int
+	TLS            bool
+	TLSCaPath      string
+	SentinelMaster string // when set, Addresses are treated as Sentinel addresses
+}
+
+func redisConfigFromEnv() RedisConfig {
+	addrs := os.Getenv("REDIS")
+	if addrs == "" {
+		addrs = "localhost:6379"
+	}
+
+	db, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+
+	return RedisConfig{
+		Addresses:      strings.Split(addrs, ","),
+		Password:       os.Getenv("REDIS_PASSWORD"),
+		DB:             db,
+		TLS:            os.Getenv("REDIS_TLS") == "true",
+		TLSCaPath:      os.Getenv("REDIS_TLS_CA"),
+		SentinelMaster: os.Getenv("REDIS_SENTINEL_MASTER"),
+	}
+}
+
+func initRedis() *redis.Pool {
+	cfg := redisConfigFromEnv()
+
+	return &redis.Pool{
+		MaxIdle:     10,
+		MaxActive:   50,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redis.Conn, error) {
+			return dialRedis(cfg)
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+}
+
+func dialRedis(cfg RedisConfig) (redis.Conn, error) {
+	addr := cfg.Addresses[0]
+	if cfg.SentinelMaster != "" {
+		masterAddr, err := resolveSentinelMaster(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("resolving master %q via sentinel: %w", cfg.SentinelMaster, err)
+		}
+		addr = masterAddr
+	}
+
+	opts := []redis.DialOption{
+		redis.DialDatabase(cfg.DB),
+	}
+	if cfg.Password != "" {
+		opts = append(opts, redis.DialPassword(cfg.Password))
+	}
+	if cfg.TLS {
+		tlsConfig, err := buildRedisTLSConfig(cfg.TLSCaPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, redis.DialUseTLS(true), redis.DialTLSConfig(tlsConfig))
+	}
+
+	return redis.Dial("tcp", addr, opts...)
+}
+
+func buildRedisTLSConfig(caPath string) (*tls.Config, error) {
+	if caPath == "" {
+		return &tls.Config{MinVersion: tls.VersionTLS12}, nil
+	}
+
+	caCert, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading REDIS_TLS_CA %q: %w", caPath, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates found in REDIS_TLS_CA %q", caPath)
+	}
+
+	return &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}, nil
+}
+
+// resolveSentinelMaster asks one of the configured Sentinel addresses for the
+// current address of cfg.SentinelMaster, trying each Sentinel in turn so a
+// single down Sentinel doesn't block startup or failover recovery.
+func resolveSentinelMaster(cfg RedisConfig) (string, error) {
+	var lastErr error
+	for _, sentinelAddr := range cfg.Addresses {
+		conn, err := redis.Dial("tcp", sentinelAddr, redis.DialConnectTimeout(5*time.Second))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		reply, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", cfg.SentinelMaster))
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(reply) != 2 {
+			lastErr = fmt.Errorf("unexpected SENTINEL reply: %v", reply)
+			continue
+		}
+
+		return fmt.Sprintf("%s:%s", reply[0], reply[1]), nil
+	}
+
+	return "", fmt.Errorf("no sentinel reachable: %w", lastErr)
+}
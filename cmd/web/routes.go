@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"subscription/internal/ratelimit"
+)
+
+// rateLimit looks up bucketName in app.RateBuckets and returns middleware
+// enforcing it per client IP. A missing bucket name is a programmer error,
+// so it panics at route-registration time rather than silently letting
+// traffic through unlimited.
+func (app *Config) rateLimit(bucketName string) func(http.Handler) http.Handler {
+	return app.rateLimitBy(bucketName, ratelimit.ByIP)
+}
+
+// rateLimitByUser is rateLimit keyed on the signed-in user instead of the
+// client IP, for endpoints where the thing worth bounding is "how much one
+// account does", not "how much one address does" (e.g. several employees
+// behind the same office NAT shouldn't share a mail-send budget).
+func (app *Config) rateLimitByUser(bucketName string) func(http.Handler) http.Handler {
+	return app.rateLimitBy(bucketName, ratelimit.ByUserID(app.sessionUserID))
+}
+
+func (app *Config) rateLimitBy(bucketName string, keyFn ratelimit.KeyFunc) func(http.Handler) http.Handler {
+	bucket, ok := app.RateBuckets[bucketName]
+	if !ok {
+		panic("ratelimit: no bucket configured for " + bucketName)
+	}
+
+	return app.RateLimiter.Middleware(bucket, keyFn)
+}
+
+// sessionUserID returns the signed-in user's ID from the session, or 0 if
+// the request is unauthenticated.
+func (app *Config) sessionUserID(r *http.Request) int {
+	return app.Session.GetInt(r.Context(), "userID")
+}
+
+func (app *Config) routes() http.Handler {
+	mux := chi.NewRouter()
+
+	mux.Use(middleware.Recoverer)
+	mux.Use(app.Session.LoadAndSave)
+
+	mux.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.Route("/admin/jobs", func(mux chi.Router) {
+		mux.Get("/", app.adminListJobs)
+		mux.Post("/{name}/run", app.adminRunJob)
+	})
+
+	mux.With(app.rateLimit("login")).Post("/login", app.handleLogin)
+	mux.With(app.rateLimit("signup")).Post("/signup", app.handleSignup)
+	mux.With(app.rateLimit("password-reset")).Post("/password-reset", app.handlePasswordReset)
+	mux.With(app.rateLimit("mail-send"), app.rateLimitByUser("mail-send-user")).Post("/mail/send", app.handleMailSend)
+
+	mux.Get("/events/stream", app.serveEventStream)
+
+	mux.Route("/admin/users/{userID}", func(mux chi.Router) {
+		mux.Post("/logout", app.adminForceLogout)
+		mux.Post("/plan", app.adminChangePlan)
+	})
+
+	return mux
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/gomodule/redigo/redis"
+)
+
+// jobLockTTL bounds how long a job's distributed lock is held, so a crashed
+// replica can't block every future run of that job.
+const jobLockTTL = 5 * time.Minute
+
+// unlockScript deletes a lock key only if it still holds the token this
+// runner set, so a job that outran jobLockTTL (and whose key was then
+// re-acquired by another replica) can't delete a lock it no longer owns.
+var unlockScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+else
+  return 0
+end
+`)
+
+func initScheduler() *gocron.Scheduler {
+	s := gocron.NewScheduler(time.UTC)
+	s.StartAsync()
+	return s
+}
+
+// jobRun records a scheduled job's definition and the time of its most
+// recent execution, for the /admin/jobs listing.
+type jobRun struct {
+	Name    string
+	Cron    string
+	LastRun time.Time
+	run     func() error
+}
+
+// JobRegistry tracks the jobs registered with the scheduler so the admin
+// endpoints can list them and report when each last ran.
+type JobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*jobRun
+}
+
+func newJobRegistry() *JobRegistry {
+	return &JobRegistry{jobs: make(map[string]*jobRun)}
+}
+
+func (r *JobRegistry) list() []jobRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]jobRun, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		out = append(out, *j)
+	}
+	return out
+}
+
+func (r *JobRegistry) recordRun(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if j, ok := r.jobs[name]; ok {
+		j.LastRun = time.Now()
+	}
+}
+
+// RegisterJob schedules fn to run on cronExpr, guarded by a Redis-based
+// distributed lock keyed by name. Every replica behind the load balancer
+// registers the same job, but only the one that wins the SET NX PX race
+// actually runs it for a given tick.
+func (app *Config) RegisterJob(name, cronExpr string, fn func() error) error {
+	app.Jobs.mu.Lock()
+	app.Jobs.jobs[name] = &jobRun{Name: name, Cron: cronExpr, run: fn}
+	app.Jobs.mu.Unlock()
+
+	_, err := app.Scheduler.Cron(cronExpr).Do(func() {
+		app.runLockedJob(name, fn)
+	})
+	return err
+}
+
+// RunJobNow executes the named job immediately, bypassing the scheduler but
+// still going through the same distributed lock so a manual trigger from
+// the admin API can't race a naturally-scheduled run on another replica. The
+// returned bool reports whether this call actually ran the job; it is false
+// when the lock was held elsewhere, and also when the lock could not be taken
+// because generating the token or talking to Redis failed (both are logged).
+func (app *Config) RunJobNow(name string) (ran bool, err error) {
+	app.Jobs.mu.Lock()
+	j, ok := app.Jobs.jobs[name]
+	app.Jobs.mu.Unlock()
+
+	if !ok {
+		return false, fmt.Errorf("no such job: %s", name)
+	}
+
+	return app.runLockedJob(name, j.run), nil
+}
+
+// runLockedJob runs fn if and only if it wins the distributed lock for
+// name, and reports whether it did.
+func (app *Config) runLockedJob(name string, fn func() error) bool {
+	conn := app.RedisPool.Get()
+	defer conn.Close()
+
+	lockKey := fmt.Sprintf("job-lock:%s", name)
+	token, err := lockToken()
+	if err != nil {
+		app.ErrorLog.Println("generating lock token for job", name, err)
+		return false
+	}
+
+	reply, err := redis.String(conn.Do("SET", lockKey, token, "NX", "PX", jobLockTTL.Milliseconds()))
+	if err != nil {
+		if err != redis.ErrNil {
+			app.ErrorLog.Println("acquiring lock for job", name, err)
+		}
+		// ErrNil means another replica already holds the lock this tick
+		return false
+	}
+	if reply != "OK" {
+		return false
+	}
+	defer func() {
+		// Release promptly, but only if we still hold it: a job that ran
+		// past jobLockTTL may have had its key expire and re-acquired by
+		// another replica, and we must not delete that replica's lock.
+		if _, err := unlockScript.Do(conn, lockKey, token); err != nil {
+			app.ErrorLog.Println("releasing lock for job", name, err)
+		}
+	}()
+
+	if err := fn(); err != nil {
+		app.ErrorLog.Println("job", name, "failed:", err)
+	}
+
+	app.Jobs.recordRun(name)
+	return true
+}
+
+// lockToken generates a unique value to identify this runner's hold on a
+// job lock, so it can be released with a compare-and-delete.
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
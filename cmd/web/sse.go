@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// sseBroker fans live events out to connected browsers, keyed by user ID,
+// so a subscription change takes effect on every open tab without a page
+// reload.
+type sseBroker struct {
+	mu          sync.Mutex
+	subscribers map[int][]chan string
+}
+
+func newSSEBroker() *sseBroker {
+	return &sseBroker{subscribers: make(map[int][]chan string)}
+}
+
+func (b *sseBroker) subscribe(userID int) chan string {
+	ch := make(chan string, 4)
+
+	b.mu.Lock()
+	b.subscribers[userID] = append(b.subscribers[userID], ch)
+	b.mu.Unlock()
+
+	return ch
+}
+
+func (b *sseBroker) unsubscribe(userID int, ch chan string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subscribers[userID]
+	for i, c := range subs {
+		if c == ch {
+			b.subscribers[userID] = append(subs[:i], subs[i+1:]...)
+			close(ch)
+			break
+		}
+	}
+}
+
+func (b *sseBroker) publish(userID int, payload string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subscribers[userID] {
+		select {
+		case ch <- payload:
+		default:
+			// slow subscriber; drop rather than block the event dispatcher
+		}
+	}
+}
+
+// serveEventStream streams live events to the signed-in user over SSE.
+func (app *Config) serveEventStream(w http.ResponseWriter, r *http.Request) {
+	userID := app.Session.GetInt(r.Context(), "userID")
+	if userID == 0 {
+		http.Error(w, "not signed in", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := app.Broker.subscribe(userID)
+	defer app.Broker.unsubscribe(userID, ch)
+
+	for {
+		select {
+		case payload, ok := <-ch:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package data
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Models wraps all the data models used by the application so a single
+// value can be threaded through Config.
+type Models struct {
+	DB *sql.DB
+}
+
+// New returns a Models value backed by the given database connection.
+func New(db *sql.DB) Models {
+	return Models{DB: db}
+}
+
+// User represents an account holder in the subscription system.
+type User struct {
+	ID        int
+	Email     string
+	FirstName string
+	LastName  string
+	Password  string
+	Active    int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
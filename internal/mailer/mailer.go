@@ -0,0 +1,137 @@
+// Package mailer holds the outbound email payload and SMTP delivery logic
+// shared between the web app (which enqueues messages) and the
+// mailer-worker binary (which actually sends them).
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strconv"
+)
+
+// Message represents a single outbound email. It is JSON-serialized onto
+// the "mail" queue by the web app and deserialized by the worker.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Config holds the SMTP settings used to actually deliver a Message.
+type Config struct {
+	Domain      string
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	Encryption  string // "tls" for implicit TLS; any other value ("none", "") sends plain SMTP, upgraded via STARTTLS if the server offers it
+	FromName    string
+	FromAddress string
+}
+
+// ConfigFromEnv builds a Config from the environment, falling back to the
+// local Mailhog-style defaults used in development.
+func ConfigFromEnv() Config {
+	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if port == 0 {
+		port = 1025
+	}
+
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	return Config{
+		Domain:      host,
+		Host:        host,
+		Port:        port,
+		Username:    os.Getenv("SMTP_USERNAME"),
+		Password:    os.Getenv("SMTP_PASSWORD"),
+		Encryption:  os.Getenv("SMTP_ENCRYPTION"),
+		FromName:    "Info",
+		FromAddress: "info@mycompany.com",
+	}
+}
+
+// Send delivers msg over SMTP using the given Config. Errors returned here
+// are treated as retryable by the worker, so they should only cover
+// transport/server failures, not malformed input.
+func (c Config) Send(msg Message) error {
+	addr := fmt.Sprintf("%s:%d", c.Host, c.Port)
+
+	from := msg.From
+	if from == "" {
+		from = c.FromAddress
+	}
+
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	body := buildMessage(from, c.FromName, msg)
+
+	if c.Encryption == "tls" {
+		return c.sendTLS(addr, auth, from, msg.To, body)
+	}
+
+	if err := smtp.SendMail(addr, auth, from, []string{msg.To}, body); err != nil {
+		return fmt.Errorf("sending mail via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// sendTLS delivers a message over an SMTP connection that is TLS-wrapped
+// from the start (implicit TLS, e.g. port 465), rather than upgraded via
+// STARTTLS mid-session.
+func (c Config) sendTLS(addr string, auth smtp.Auth, from, to string, body []byte) error {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: c.Host, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		return fmt.Errorf("dialing %s over TLS: %w", addr, err)
+	}
+	defer conn.Close()
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		return fmt.Errorf("creating SMTP client: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authenticating with %s: %w", addr, err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("RCPT TO: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("writing message body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("closing DATA writer: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func buildMessage(from, fromName string, msg Message) []byte {
+	headers := fmt.Sprintf(
+		"From: %s <%s>\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n",
+		fromName, from, msg.To, msg.Subject,
+	)
+	return []byte(headers + msg.Body)
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBuckets are used for any endpoint whose RATE_LIMIT_<NAME> env var
+// isn't set.
+var defaultBuckets = map[string]Bucket{
+	"login":          {Name: "login", Rate: 5, Period: 15 * time.Minute, Burst: 2},
+	"signup":         {Name: "signup", Rate: 5, Period: time.Hour, Burst: 1},
+	"password-reset": {Name: "password-reset", Rate: 3, Period: 15 * time.Minute, Burst: 1},
+	"mail-send":      {Name: "mail-send", Rate: 20, Period: time.Minute, Burst: 5},
+	"mail-send-user": {Name: "mail-send-user", Rate: 10, Period: time.Minute, Burst: 2},
+}
+
+// BucketsFromEnv returns the default buckets, overriding any whose
+// RATE_LIMIT_<NAME> environment variable is set, in "rate:period:burst"
+// form, e.g. RATE_LIMIT_LOGIN="5:15m:2".
+func BucketsFromEnv() (map[string]Bucket, error) {
+	buckets := make(map[string]Bucket, len(defaultBuckets))
+	for name, b := range defaultBuckets {
+		buckets[name] = b
+	}
+
+	for name := range defaultBuckets {
+		envName := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		raw := os.Getenv(envName)
+		if raw == "" {
+			continue
+		}
+
+		b, err := parseBucket(name, raw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", envName, err)
+		}
+		buckets[name] = b
+	}
+
+	return buckets, nil
+}
+
+func parseBucket(name, raw string) (Bucket, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) != 3 {
+		return Bucket{}, fmt.Errorf("expected rate:period:burst, got %q", raw)
+	}
+
+	rate, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Bucket{}, fmt.Errorf("invalid rate %q: %w", parts[0], err)
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return Bucket{}, fmt.Errorf("invalid period %q: %w", parts[1], err)
+	}
+
+	burst, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return Bucket{}, fmt.Errorf("invalid burst %q: %w", parts[2], err)
+	}
+
+	b := Bucket{Name: name, Rate: rate, Period: period, Burst: burst}
+	if err := validateBucket(b); err != nil {
+		return Bucket{}, err
+	}
+
+	return b, nil
+}
+
+// validateBucket rejects configurations that would make Allow's GCRA math
+// divide by zero or otherwise never allow a request through.
+func validateBucket(b Bucket) error {
+	if b.Rate <= 0 {
+		return fmt.Errorf("rate must be > 0, got %d", b.Rate)
+	}
+	if b.Period <= 0 {
+		return fmt.Errorf("period must be > 0, got %s", b.Period)
+	}
+	if b.Period.Milliseconds()/int64(b.Rate) < 1 {
+		return fmt.Errorf("period %s is too short for rate %d: under 1ms per request", b.Period, b.Rate)
+	}
+	if b.Burst < 1 {
+		return fmt.Errorf("burst must be >= 1, got %d", b.Burst)
+	}
+	return nil
+}
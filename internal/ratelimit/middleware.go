@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// KeyFunc extracts the identity a bucket should be keyed on (IP, user ID,
+// ...) from the incoming request.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys on the client's remote IP, stripping the port.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByUserID builds a KeyFunc for limits that should apply per account rather
+// than per IP (e.g. a shared office NAT shouldn't throttle every employee
+// together). getUserID is left to the caller since extracting it means
+// touching the session store, which this package doesn't know about.
+// Requests with no authenticated user (getUserID returns 0) fall back to
+// ByIP so the bucket still has a deterministic key.
+func ByUserID(getUserID func(r *http.Request) int) KeyFunc {
+	return func(r *http.Request) string {
+		if id := getUserID(r); id != 0 {
+			return strconv.Itoa(id)
+		}
+		return ByIP(r)
+	}
+}
+
+// Middleware enforces bucket against incoming requests, keyed by keyFn, and
+// sets the standard X-RateLimit-* / Retry-After headers.
+func (l *Limiter) Middleware(bucket Bucket, keyFn KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := l.Allow(bucket, keyFn(r))
+			if err != nil {
+				// Fail open: a Redis hiccup shouldn't take down login/signup.
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(bucket.Rate))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package ratelimit implements GCRA (generic cell rate algorithm) rate
+// limiting against Redis, so limits are shared across every web replica
+// instead of living in each process's memory.
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Bucket describes a GCRA limit: Rate requests per Period on average, with
+// up to Burst requests (at least 1) admitted back to back to absorb short
+// spikes.
+type Bucket struct {
+	Name   string
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// gcraScript atomically computes and stores the new theoretical arrival
+// time (TAT) for key: tat = max(now, storedTAT) + emissionInterval; the
+// request is rejected if that would put tat further than burstOffset ahead
+// of now. Returns {allowed (0/1), tat_after_update}; a rejected request's
+// tat_after_update is not stored.
+var gcraScript = redis.NewScript(1, `
+local key = KEYS[1]
+local emission_interval = tonumber(ARGV[1])
+local burst_offset = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl_ms = tonumber(ARGV[4])
+
+local tat = tonumber(redis.call("GET", key))
+if tat == nil or tat < now then
+  tat = now
+end
+
+local new_tat = tat + emission_interval
+if new_tat - now > burst_offset then
+  return {0, new_tat}
+end
+
+redis.call("SET", key, new_tat, "PX", ttl_ms)
+return {1, new_tat}
+`)
+
+// Limiter applies GCRA buckets against a shared Redis pool.
+type Limiter struct {
+	pool *redis.Pool
+}
+
+// New returns a Limiter backed by pool.
+func New(pool *redis.Pool) *Limiter {
+	return &Limiter{pool: pool}
+}
+
+// Result is the outcome of a single Allow check.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Allow reports whether a request against b, identified by key (e.g. an IP
+// address or user ID), is allowed right now.
+func (l *Limiter) Allow(b Bucket, key string) (Result, error) {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	emissionInterval := b.Period.Milliseconds() / int64(b.Rate)
+	burstOffset := emissionInterval * int64(b.Burst)
+	now := time.Now().UnixMilli()
+	ttl := emissionInterval*int64(b.Burst+1) + int64(time.Second/time.Millisecond)
+
+	redisKey := fmt.Sprintf("ratelimit:%s:%s", b.Name, key)
+	reply, err := redis.Int64s(gcraScript.Do(conn, redisKey, emissionInterval, burstOffset, now, ttl))
+	if err != nil {
+		return Result{}, fmt.Errorf("evaluating GCRA script: %w", err)
+	}
+
+	allowed := reply[0] == 1
+	tat := reply[1]
+
+	remaining := int((burstOffset - (tat - now)) / emissionInterval)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	var retryAfter time.Duration
+	if !allowed {
+		retryAfter = time.Duration(tat-burstOffset-now) * time.Millisecond
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+	}
+
+	return Result{Allowed: allowed, Remaining: remaining, RetryAfter: retryAfter}, nil
+}